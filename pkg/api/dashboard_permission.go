@@ -0,0 +1,382 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// swagger:route GET /dashboards/uid/{uid}/permissions dashboard_permissions getDashboardPermissionsListByUID
+//
+// Gets all existing permissions for the given dashboard.
+//
+// Responses:
+// 200: getDashboardPermissionsListResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) GetDashboardPermissionList(c *contextmodel.ReqContext) response.Response {
+	uid := web.Params(c.Req)[":uid"]
+
+	dash, err := hs.DashboardService.GetDashboard(c.Req.Context(), &dashboards.GetDashboardQuery{UID: uid, OrgID: c.OrgID})
+	if err != nil {
+		return response.Err(err)
+	}
+
+	permissions, err := hs.dashboardPermissionsService.GetPermissions(c.Req.Context(), c.SignedInUser, strconv.FormatInt(dash.ID, 10))
+	if err != nil {
+		return response.Err(err)
+	}
+
+	filtered := make([]*DashboardACLInfoWithScopeDTO, 0, len(permissions))
+	for _, p := range permissions {
+		if hs.isHiddenUser(p.UserLogin, c) {
+			continue
+		}
+		filtered = append(filtered, hs.permissionToACLInfoDTO(dash, p))
+	}
+
+	return response.JSON(http.StatusOK, filtered)
+}
+
+// isHiddenUser reports whether a permission entry belongs to a user that should
+// be hidden from the ACL listing, unless it's the signed in user looking at their own entry.
+func (hs *HTTPServer) isHiddenUser(login string, c *contextmodel.ReqContext) bool {
+	if login == "" || login == c.SignedInUser.GetLogin() {
+		return false
+	}
+	_, hidden := hs.Cfg.HiddenUsers[login]
+	return hidden
+}
+
+// DashboardACLInfoWithScopeDTO extends the base ACL entry with the folder
+// path prefix it was scoped to, if any, so UIs can tell a role grant that's
+// scoped to a subset of folders apart from one that applies everywhere.
+type DashboardACLInfoWithScopeDTO struct {
+	*dashboards.DashboardACLInfoDTO
+	PathPrefix string `json:"pathPrefix,omitempty"`
+}
+
+func (hs *HTTPServer) permissionToACLInfoDTO(dash *dashboards.Dashboard, p accesscontrol.ResourcePermission) *DashboardACLInfoWithScopeDTO {
+	return &DashboardACLInfoWithScopeDTO{
+		DashboardACLInfoDTO: &dashboards.DashboardACLInfoDTO{
+			DashboardID: dash.ID,
+			UserID:      p.UserID,
+			UserLogin:   p.UserLogin,
+			UserEmail:   p.UserEmail,
+			TeamID:      p.TeamID,
+			Team:        p.Team,
+			Role:        p.BuiltInRole,
+			Permission:  hs.dashboardPermissionsService.MapActions(p),
+			Inherited:   dash.FolderID != 0 && p.IsInherited,
+		},
+		PathPrefix: pathPrefixFromScope(p.Scope),
+	}
+}
+
+const folderPathScopePrefix = "folders:path:"
+
+// pathPrefixFromScope extracts the folder path prefix a built-in role
+// permission was scoped to, e.g. "folders:path:finance/*" -> "finance/*".
+// Permissions that aren't scoped to a folder path return an empty prefix.
+func pathPrefixFromScope(scope string) string {
+	if !strings.HasPrefix(scope, folderPathScopePrefix) {
+		return ""
+	}
+	return strings.TrimPrefix(scope, folderPathScopePrefix)
+}
+
+// folderPathScope builds the scope a built-in role permission is checked
+// and stored against when it's restricted to a folder path prefix.
+func folderPathScope(pathPrefix string) string {
+	return folderPathScopePrefix + pathPrefix
+}
+
+// swagger:route POST /dashboards/uid/{uid}/permissions dashboard_permissions updateDashboardPermissionsByUID
+//
+// Updates permissions for a dashboard.
+//
+// This operation will remove existing permissions if they're not included in the request.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) UpdateDashboardPermissions(c *contextmodel.ReqContext, apiCmd UpdateDashboardPermissionsCommand) response.Response {
+	if err := validateDashboardACLUpdateItems(apiCmd.Items); err != nil {
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+
+	if err := authorizeBuiltInRoleSelectors(c, apiCmd.BuiltInRoles); err != nil {
+		return response.Error(http.StatusForbidden, err.Error(), err)
+	}
+
+	uid := web.Params(c.Req)[":uid"]
+	dash, err := hs.DashboardService.GetDashboard(c.Req.Context(), &dashboards.GetDashboardQuery{UID: uid, OrgID: c.OrgID})
+	if err != nil {
+		return response.Err(err)
+	}
+
+	previous, err := hs.dashboardPermissionsService.GetPermissions(c.Req.Context(), c.SignedInUser, strconv.FormatInt(dash.ID, 10))
+	if err != nil {
+		return response.Err(err)
+	}
+
+	if err := hs.applyDashboardACLUpdate(c.Req.Context(), c.OrgID, dash, apiCmd.Items); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to update dashboard permissions", err)
+	}
+
+	if err := hs.applyBuiltInRolePermissions(c.Req.Context(), c.OrgID, dash, apiCmd.BuiltInRoles); err != nil {
+		// The ACL items write above already landed, so roll it back to avoid
+		// leaving the dashboard on a half-applied permission set with no
+		// audit trail for the change that did happen.
+		rollbackItems := resourcePermissionsToACLUpdateItems(previous)
+		if rollbackErr := hs.applyDashboardACLUpdate(c.Req.Context(), c.OrgID, dash, rollbackItems); rollbackErr != nil {
+			hs.log.Error("failed to roll back dashboard ACL items after built-in role grant failure", "dashboardUid", dash.UID, "error", rollbackErr)
+			return response.Error(http.StatusInternalServerError, "Failed to update dashboard permissions, and rolling back the already-applied ACL items also failed; the dashboard's permissions may be left in an inconsistent state", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to update dashboard permissions, ACL items were rolled back", err)
+	}
+
+	hs.recordDashboardACLAudit(c, dash, previous, apiCmd.Items, apiCmd.BuiltInRoles)
+
+	return response.Success("Dashboard permissions updated")
+}
+
+// UpdateDashboardPermissionsCommand is the request body accepted by
+// UpdateDashboardPermissions. It extends the base ACL update payload with an
+// optional set of built-in-role selectors, each granting a permission to
+// every member of an org role, optionally scoped to a folder path prefix.
+type UpdateDashboardPermissionsCommand struct {
+	dtos.UpdateDashboardACLCommand
+	BuiltInRoles []BuiltInRolePermissionSelector `json:"builtInRoles,omitempty"`
+}
+
+// BuiltInRolePermissionSelector grants a permission to every user holding
+// the given org role, optionally restricted to dashboards under PathPrefix.
+type BuiltInRolePermissionSelector struct {
+	Role       org.RoleType              `json:"role"`
+	PathPrefix string                    `json:"pathPrefix,omitempty"`
+	Permission dashboards.PermissionType `json:"permission"`
+}
+
+// authorizeBuiltInRoleSelectors ensures the caller has write-permission on
+// every folder path prefix a selector would scope a grant to. The route
+// itself only authorizes the caller against the single dashboard in the
+// URL, so without this check a user with write-permission on just that one
+// dashboard could use PathPrefix to grant an org role access across an
+// entire folder tree they otherwise have no rights over.
+func authorizeBuiltInRoleSelectors(c *contextmodel.ReqContext, selectors []BuiltInRolePermissionSelector) error {
+	for _, selector := range selectors {
+		if selector.PathPrefix == "" {
+			continue
+		}
+		if !c.SignedInUser.HasPermission(dashboards.ActionDashboardsPermissionsWrite, folderPathScope(selector.PathPrefix)) {
+			return fmt.Errorf("user is missing permission to grant access scoped to folder path %q", selector.PathPrefix)
+		}
+	}
+	return nil
+}
+
+// applyBuiltInRolePermissions grants each selector's permission to its org
+// role, scoped to its folder path prefix when one is given.
+func (hs *HTTPServer) applyBuiltInRolePermissions(ctx context.Context, orgID int64, dash *dashboards.Dashboard, selectors []BuiltInRolePermissionSelector) error {
+	for _, selector := range selectors {
+		if _, err := hs.dashboardPermissionsService.SetBuiltInRolePermissions(ctx, orgID, dash.ID, string(selector.Role), selector.PathPrefix, string(selector.Permission)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateDashboardACLUpdateItems enforces that every ACL item targets at most
+// one of user, team or built-in role. An item that sets none of them passes.
+func validateDashboardACLUpdateItems(items []dtos.DashboardACLUpdateItem) error {
+	for _, item := range items {
+		if item.UserID > 0 && item.TeamID > 0 {
+			return errors.New("cannot specify both userId and teamId for an ACL item")
+		}
+		if item.TeamID > 0 && item.Role != nil {
+			return errors.New("cannot specify both teamId and role for an ACL item")
+		}
+		if item.UserID > 0 && item.Role != nil {
+			return errors.New("cannot specify both userId and role for an ACL item")
+		}
+	}
+	return nil
+}
+
+// applyDashboardACLUpdate translates the requested ACL items into permission
+// commands and replaces the dashboard's existing permission set with them.
+func (hs *HTTPServer) applyDashboardACLUpdate(ctx context.Context, orgID int64, dash *dashboards.Dashboard, items []dtos.DashboardACLUpdateItem) error {
+	commands := make([]accesscontrol.SetResourcePermissionCommand, 0, len(items))
+	for _, item := range items {
+		commands = append(commands, accesscontrol.SetResourcePermissionCommand{
+			UserID:      item.UserID,
+			TeamID:      item.TeamID,
+			BuiltinRole: roleOrEmpty(item.Role),
+			Permission:  string(item.Permission),
+		})
+	}
+
+	_, err := hs.dashboardPermissionsService.SetPermissions(ctx, orgID, strconv.FormatInt(dash.ID, 10), commands...)
+	return err
+}
+
+// resourcePermissionsToACLUpdateItems converts a previously read permission
+// set back into update items, so it can be re-applied verbatim during rollback.
+// Inherited entries come from a parent folder rather than the dashboard's own
+// ACL, so they're skipped here too; otherwise a rollback would write them
+// back as direct, non-inherited grants instead of restoring the dashboard's
+// original permission model.
+func resourcePermissionsToACLUpdateItems(permissions []accesscontrol.ResourcePermission) []dtos.DashboardACLUpdateItem {
+	items := make([]dtos.DashboardACLUpdateItem, 0, len(permissions))
+	for _, p := range permissions {
+		if p.IsInherited {
+			continue
+		}
+		item := dtos.DashboardACLUpdateItem{
+			UserID:     p.UserID,
+			TeamID:     p.TeamID,
+			Permission: dashboards.PermissionType(p.Permission),
+		}
+		if p.BuiltInRole != "" {
+			role := org.RoleType(p.BuiltInRole)
+			item.Role = &role
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+func roleOrEmpty(role *org.RoleType) string {
+	if role == nil {
+		return ""
+	}
+	return string(*role)
+}
+
+// BatchUpdateDashboardPermissionsCommand applies a set of dashboard ACL updates
+// across many dashboards in a single request.
+//
+// swagger:model
+type BatchUpdateDashboardPermissionsCommand struct {
+	Items []DashboardPermissionsBatchItem `json:"items"`
+}
+
+// DashboardPermissionsBatchItem is one dashboard's worth of ACL updates within
+// a BatchUpdateDashboardPermissionsCommand.
+type DashboardPermissionsBatchItem struct {
+	DashboardUID string                        `json:"dashboardUid"`
+	Items        []dtos.DashboardACLUpdateItem `json:"items"`
+}
+
+// dashboardACLBatchUpdate pairs a resolved dashboard and its requested ACL
+// items with the permission set it had before the batch started, so a failed
+// item can have its predecessors rolled back.
+type dashboardACLBatchUpdate struct {
+	dash     *dashboards.Dashboard
+	previous []accesscontrol.ResourcePermission
+	items    []dtos.DashboardACLUpdateItem
+}
+
+// swagger:route POST /dashboards/permissions:batch dashboard_permissions batchUpdateDashboardPermissions
+//
+// Atomically updates permissions across many dashboards in a single request.
+//
+// Every item is validated and every dashboard's write permission is checked
+// before any mutation happens. If applying any one dashboard's permissions
+// fails, the dashboards already updated in this batch are rolled back to the
+// permission set they had before the request. This avoids the N round-trips
+// needed when provisioning permissions for many dashboards at once, e.g. from
+// Terraform or grafonnet.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) BatchUpdateDashboardPermissions(c *contextmodel.ReqContext, cmd BatchUpdateDashboardPermissionsCommand) response.Response {
+	if len(cmd.Items) == 0 {
+		return response.Error(http.StatusBadRequest, "no items provided", nil)
+	}
+
+	updates := make([]dashboardACLBatchUpdate, 0, len(cmd.Items))
+
+	for _, item := range cmd.Items {
+		if err := validateDashboardACLUpdateItems(item.Items); err != nil {
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		}
+
+		dash, err := hs.DashboardService.GetDashboard(c.Req.Context(), &dashboards.GetDashboardQuery{UID: item.DashboardUID, OrgID: c.OrgID})
+		if err != nil {
+			return response.Err(err)
+		}
+
+		scope := dashboards.ScopeDashboardsProvider.GetResourceScopeUID(item.DashboardUID)
+		if !c.SignedInUser.HasPermission(dashboards.ActionDashboardsPermissionsWrite, scope) {
+			return response.Error(http.StatusForbidden, "user is missing permission to update permissions on dashboard "+item.DashboardUID, nil)
+		}
+
+		previous, err := hs.dashboardPermissionsService.GetPermissions(c.Req.Context(), c.SignedInUser, strconv.FormatInt(dash.ID, 10))
+		if err != nil {
+			return response.Err(err)
+		}
+
+		updates = append(updates, dashboardACLBatchUpdate{dash: dash, previous: previous, items: item.Items})
+	}
+
+	applied := make([]dashboardACLBatchUpdate, 0, len(updates))
+	for _, u := range updates {
+		if err := hs.applyDashboardACLUpdate(c.Req.Context(), c.OrgID, u.dash, u.items); err != nil {
+			if failed := hs.rollbackDashboardACLUpdates(c.Req.Context(), c.OrgID, applied); len(failed) > 0 {
+				return response.Error(http.StatusInternalServerError,
+					fmt.Sprintf("Failed to apply batch dashboard permissions, and rollback also failed for dashboards %s; their permissions may be left in an inconsistent state", strings.Join(failed, ", ")),
+					err)
+			}
+			return response.Error(http.StatusInternalServerError, "Failed to apply batch dashboard permissions, all changes were rolled back", err)
+		}
+		applied = append(applied, u)
+	}
+
+	for _, u := range applied {
+		hs.recordDashboardACLAudit(c, u.dash, u.previous, u.items, nil)
+	}
+
+	return response.Success("Dashboard permissions updated")
+}
+
+// rollbackDashboardACLUpdates restores the previous permission set for each
+// dashboard already updated in a batch that subsequently failed, so a partial
+// failure never leaves some dashboards on new permissions and others on old
+// ones. It returns the UIDs of dashboards whose compensating re-apply itself
+// failed, so the caller can tell the client which dashboards may still be on
+// the new (unwanted) permissions instead of asserting the rollback succeeded.
+func (hs *HTTPServer) rollbackDashboardACLUpdates(ctx context.Context, orgID int64, applied []dashboardACLBatchUpdate) []string {
+	var failed []string
+	for _, u := range applied {
+		items := resourcePermissionsToACLUpdateItems(u.previous)
+		if err := hs.applyDashboardACLUpdate(ctx, orgID, u.dash, items); err != nil {
+			hs.log.Error("failed to roll back dashboard permissions after batch update failure", "dashboardUid", u.dash.UID, "error", err)
+			failed = append(failed, u.dash.UID)
+		}
+	}
+	return failed
+}
@@ -0,0 +1,223 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// AuditSink persists dashboard ACL mutations so they can be reviewed later.
+// HTTPServer.auditSink is pluggable so deployments can route audit records
+// to whichever store fits them, e.g. the application database or a
+// JSON-lines file for shipping to an external log pipeline.
+type AuditSink interface {
+	// RecordDashboardPermissionChange stores a single audit record for a
+	// successful dashboard ACL mutation.
+	RecordDashboardPermissionChange(ctx context.Context, record DashboardPermissionAuditRecord) error
+	// ListDashboardPermissionAudit returns audit records for a dashboard,
+	// newest first, along with the total number of matching records.
+	ListDashboardPermissionAudit(ctx context.Context, orgID int64, dashboardUID string, limit, offset int) ([]DashboardPermissionAuditRecord, int64, error)
+}
+
+// DashboardPermissionAuditRecord describes a single successful dashboard ACL
+// mutation: who made it, which dashboard it applied to, and what changed.
+type DashboardPermissionAuditRecord struct {
+	ID           int64                  `json:"id"`
+	Timestamp    time.Time              `json:"timestamp"`
+	RequestID    string                 `json:"requestId,omitempty"`
+	OrgID        int64                  `json:"orgId"`
+	ActorUserID  int64                  `json:"actorUserId"`
+	ActorLogin   string                 `json:"actorLogin"`
+	DashboardUID string                 `json:"dashboardUid"`
+	Added        []PermissionAuditEntry `json:"added,omitempty"`
+	Removed      []PermissionAuditEntry `json:"removed,omitempty"`
+	Changed      []PermissionAuditEntry `json:"changed,omitempty"`
+}
+
+// PermissionAuditEntry is the audit-log representation of one ACL grant,
+// identifying its subject (user, team or built-in role, optionally scoped to
+// a folder path prefix) and permission.
+type PermissionAuditEntry struct {
+	UserID     int64  `json:"userId,omitempty"`
+	TeamID     int64  `json:"teamId,omitempty"`
+	Role       string `json:"role,omitempty"`
+	PathPrefix string `json:"pathPrefix,omitempty"`
+	Permission string `json:"permission,omitempty"`
+}
+
+// permissionAuditKey identifies the subject of an ACL entry, independent of
+// which permission it grants, so a before/after comparison can line entries
+// up regardless of ordering. pathPrefix is part of the key because a
+// built-in role can hold distinct grants at different folder path prefixes;
+// without it those grants would collide and one would disappear from the diff.
+type permissionAuditKey struct {
+	userID     int64
+	teamID     int64
+	role       string
+	pathPrefix string
+}
+
+func aclUpdateItemAuditKey(item dtos.DashboardACLUpdateItem) permissionAuditKey {
+	key := permissionAuditKey{userID: item.UserID, teamID: item.TeamID}
+	if item.Role != nil {
+		key.role = string(*item.Role)
+	}
+	return key
+}
+
+func builtInRoleSelectorAuditKey(selector BuiltInRolePermissionSelector) permissionAuditKey {
+	return permissionAuditKey{role: string(selector.Role), pathPrefix: selector.PathPrefix}
+}
+
+func resourcePermissionAuditKey(p accesscontrol.ResourcePermission) permissionAuditKey {
+	return permissionAuditKey{
+		userID:     p.UserID,
+		teamID:     p.TeamID,
+		role:       p.BuiltInRole,
+		pathPrefix: pathPrefixFromScope(p.Scope),
+	}
+}
+
+func (k permissionAuditKey) toEntry(permission string) PermissionAuditEntry {
+	return PermissionAuditEntry{UserID: k.userID, TeamID: k.teamID, Role: k.role, PathPrefix: k.pathPrefix, Permission: permission}
+}
+
+// diffDashboardACLChange compares the permission set a dashboard had before a
+// request against the items and built-in-role selectors that request
+// applied, producing the added, removed and changed entries for an audit
+// record.
+func diffDashboardACLChange(previous []accesscontrol.ResourcePermission, items []dtos.DashboardACLUpdateItem, builtInRoles []BuiltInRolePermissionSelector) (added, removed, changed []PermissionAuditEntry) {
+	before := make(map[permissionAuditKey]string, len(previous))
+	for _, p := range previous {
+		// Inherited entries come from a parent folder and aren't managed by
+		// this dashboard's ACL update, so they're never "removed" by it.
+		if p.IsInherited {
+			continue
+		}
+		before[resourcePermissionAuditKey(p)] = p.Permission
+	}
+
+	after := make(map[permissionAuditKey]string, len(items)+len(builtInRoles))
+	for _, item := range items {
+		after[aclUpdateItemAuditKey(item)] = string(item.Permission)
+	}
+	for _, selector := range builtInRoles {
+		after[builtInRoleSelectorAuditKey(selector)] = string(selector.Permission)
+	}
+
+	for key, permission := range after {
+		prevPermission, existed := before[key]
+		switch {
+		case !existed:
+			added = append(added, key.toEntry(permission))
+		case prevPermission != permission:
+			changed = append(changed, key.toEntry(permission))
+		}
+	}
+
+	for key, permission := range before {
+		if _, stillPresent := after[key]; !stillPresent {
+			removed = append(removed, key.toEntry(permission))
+		}
+	}
+
+	return added, removed, changed
+}
+
+// recordDashboardACLAudit builds and stores an audit record for a successful
+// ACL mutation. Failures to persist the audit record are logged but never
+// turned into an error response, since the permission change itself already
+// succeeded.
+func (hs *HTTPServer) recordDashboardACLAudit(c *contextmodel.ReqContext, dash *dashboards.Dashboard, previous []accesscontrol.ResourcePermission, items []dtos.DashboardACLUpdateItem, builtInRoles []BuiltInRolePermissionSelector) {
+	if hs.auditSink == nil {
+		return
+	}
+
+	added, removed, changed := diffDashboardACLChange(previous, items, builtInRoles)
+	if len(added) == 0 && len(removed) == 0 && len(changed) == 0 {
+		return
+	}
+
+	actorLogin := c.SignedInUser.GetLogin()
+	if _, hidden := hs.Cfg.HiddenUsers[actorLogin]; hidden {
+		// Keep the actor identifiable internally by ID, but don't leak a
+		// hidden user's login to whoever later reads the audit log.
+		actorLogin = ""
+	}
+
+	record := DashboardPermissionAuditRecord{
+		Timestamp:    time.Now(),
+		RequestID:    c.Req.Header.Get("X-Request-Id"),
+		OrgID:        c.OrgID,
+		ActorUserID:  c.SignedInUser.GetID(),
+		ActorLogin:   actorLogin,
+		DashboardUID: dash.UID,
+		Added:        added,
+		Removed:      removed,
+		Changed:      changed,
+	}
+
+	if err := hs.auditSink.RecordDashboardPermissionChange(c.Req.Context(), record); err != nil {
+		hs.log.Error("failed to record dashboard permission audit entry", "dashboardUid", dash.UID, "error", err)
+	}
+}
+
+// swagger:route GET /dashboards/uid/{uid}/permissions/audit dashboard_permissions getDashboardPermissionAuditLog
+//
+// Gets the paginated history of permission changes for the given dashboard.
+//
+// Responses:
+// 200: getDashboardPermissionAuditLogResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) GetDashboardPermissionAuditLog(c *contextmodel.ReqContext) response.Response {
+	uid := web.Params(c.Req)[":uid"]
+
+	dash, err := hs.DashboardService.GetDashboard(c.Req.Context(), &dashboards.GetDashboardQuery{UID: uid, OrgID: c.OrgID})
+	if err != nil {
+		return response.Err(err)
+	}
+
+	if hs.auditSink == nil {
+		return response.JSON(http.StatusOK, dashboardPermissionAuditLogResponse{Records: []DashboardPermissionAuditRecord{}, Total: 0})
+	}
+
+	limit := 50
+	if v := c.Query("limit"); v != "" {
+		if parsed, err := strconv.Atoi(v); err == nil && parsed > 0 {
+			limit = parsed
+		}
+	}
+	offset := 0
+	if v := c.Query("offset"); v != "" {
+		if parsed, err := strconv.Atoi(v); err == nil && parsed >= 0 {
+			offset = parsed
+		}
+	}
+
+	records, total, err := hs.auditSink.ListDashboardPermissionAudit(c.Req.Context(), c.OrgID, dash.UID, limit, offset)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to list dashboard permission audit log", err)
+	}
+
+	return response.JSON(http.StatusOK, dashboardPermissionAuditLogResponse{Records: records, Total: total})
+}
+
+// dashboardPermissionAuditLogResponse is the paginated response body for
+// GetDashboardPermissionAuditLog.
+//
+// swagger:model
+type dashboardPermissionAuditLogResponse struct {
+	Records []DashboardPermissionAuditRecord `json:"records"`
+	Total   int64                            `json:"total"`
+}
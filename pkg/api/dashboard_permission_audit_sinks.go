@@ -0,0 +1,152 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+// dbAuditSink stores dashboard permission audit records in the application
+// database, alongside the other Grafana entities.
+type dbAuditSink struct {
+	store db.DB
+}
+
+// NewDBAuditSink returns an AuditSink backed by the application database.
+func NewDBAuditSink(store db.DB) AuditSink {
+	return &dbAuditSink{store: store}
+}
+
+func (s *dbAuditSink) RecordDashboardPermissionChange(ctx context.Context, record DashboardPermissionAuditRecord) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(&record)
+		return err
+	})
+}
+
+func (s *dbAuditSink) ListDashboardPermissionAudit(ctx context.Context, orgID int64, dashboardUID string, limit, offset int) ([]DashboardPermissionAuditRecord, int64, error) {
+	var records []DashboardPermissionAuditRecord
+	var total int64
+
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		query := sess.Where("org_id = ? AND dashboard_uid = ?", orgID, dashboardUID)
+
+		count, err := query.Clone().Count(&DashboardPermissionAuditRecord{})
+		if err != nil {
+			return err
+		}
+		total = count
+
+		return query.Desc("timestamp").Limit(limit, offset).Find(&records)
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return records, total, nil
+}
+
+// fileAuditSink appends dashboard permission audit records as JSON-lines to a
+// file, for deployments that ship audit trails to an external log pipeline
+// instead of querying them back through the Grafana API.
+type fileAuditSink struct {
+	path string
+	mu   sync.Mutex
+	next int64
+}
+
+// NewFileAuditSink returns an AuditSink that appends one JSON object per line
+// to the file at path, creating it if it doesn't already exist. If the file
+// already has records, new IDs continue on from the highest one found so a
+// restart doesn't reuse IDs already written to disk.
+func NewFileAuditSink(path string) AuditSink {
+	sink := &fileAuditSink{path: path}
+
+	f, err := os.Open(path)
+	if err == nil {
+		defer func() { _ = f.Close() }()
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			var record DashboardPermissionAuditRecord
+			if err := json.Unmarshal(scanner.Bytes(), &record); err == nil && record.ID > sink.next {
+				sink.next = record.ID
+			}
+		}
+	}
+
+	return sink
+}
+
+func (s *fileAuditSink) RecordDashboardPermissionChange(_ context.Context, record DashboardPermissionAuditRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.next++
+	record.ID = s.next
+
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("opening audit log file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("marshaling audit record: %w", err)
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("writing audit record: %w", err)
+	}
+
+	return nil
+}
+
+func (s *fileAuditSink) ListDashboardPermissionAudit(_ context.Context, orgID int64, dashboardUID string, limit, offset int) ([]DashboardPermissionAuditRecord, int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return []DashboardPermissionAuditRecord{}, 0, nil
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("opening audit log file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var matched []DashboardPermissionAuditRecord
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var record DashboardPermissionAuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			continue
+		}
+		if record.OrgID == orgID && record.DashboardUID == dashboardUID {
+			matched = append(matched, record)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, 0, fmt.Errorf("reading audit log file: %w", err)
+	}
+
+	// Newest first, matching the DB sink's ordering.
+	for i, j := 0, len(matched)-1; i < j; i, j = i+1, j-1 {
+		matched[i], matched[j] = matched[j], matched[i]
+	}
+
+	total := int64(len(matched))
+	if offset >= len(matched) {
+		return []DashboardPermissionAuditRecord{}, total, nil
+	}
+	end := offset + limit
+	if end > len(matched) {
+		end = len(matched)
+	}
+
+	return matched[offset:end], total, nil
+}
@@ -1,7 +1,9 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strings"
 	"testing"
@@ -78,6 +80,34 @@ func TestHTTPServer_GetDashboardPermissionList(t *testing.T) {
 		assert.Equal(t, result[0].UserLogin, "regular")
 		require.NoError(t, res.Body.Close())
 	})
+
+	t.Run("should tag a built-in role permission with its folder path-scope", func(t *testing.T) {
+		server := SetupAPITestServer(t, func(hs *HTTPServer) {
+			svc := dashboards.NewFakeDashboardService(t)
+			svc.On("GetDashboard", mock.Anything, mock.Anything).Return(&dashboards.Dashboard{ID: 1, UID: "1"}, nil)
+
+			hs.DashboardService = svc
+			hs.dashboardPermissionsService = &actest.FakePermissionsService{
+				ExpectedPermissions: []accesscontrol.ResourcePermission{
+					{BuiltInRole: "Editor", Scope: "folders:path:finance/*"},
+				},
+			}
+		})
+
+		res, err := server.Send(webtest.RequestWithSignedInUser(server.NewGetRequest("/api/dashboards/uid/1/permissions"), userWithPermissions(1, []accesscontrol.Permission{
+			{Action: dashboards.ActionDashboardsPermissionsRead, Scope: "dashboards:uid:1"},
+		})))
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		var result []DashboardACLInfoWithScopeDTO
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&result))
+
+		require.Len(t, result, 1)
+		assert.Equal(t, "finance/*", result[0].PathPrefix)
+		require.NoError(t, res.Body.Close())
+	})
 }
 
 func TestHTTPServer_UpdateDashboardPermissions(t *testing.T) {
@@ -109,10 +139,112 @@ func TestHTTPServer_UpdateDashboardPermissions(t *testing.T) {
 		require.NoError(t, res.Body.Close())
 	})
 
+	t.Run("should record an audit entry when permissions are successfully updated", func(t *testing.T) {
+		spy := &spyAuditSink{}
+		server := SetupAPITestServer(t, func(hs *HTTPServer) {
+			svc := dashboards.NewFakeDashboardService(t)
+			svc.On("GetDashboard", mock.Anything, mock.Anything).Return(&dashboards.Dashboard{ID: 1, UID: "1"}, nil)
+
+			hs.DashboardService = svc
+			hs.dashboardPermissionsService = &actest.FakePermissionsService{}
+			hs.auditSink = spy
+		})
+
+		body := `{"items": [{ "userId": 1, "permission": "View" }]}`
+		res, err := server.SendJSON(webtest.RequestWithSignedInUser(server.NewPostRequest("/api/dashboards/uid/1/permissions", strings.NewReader(body)), userWithPermissions(1, []accesscontrol.Permission{
+			{Action: dashboards.ActionDashboardsPermissionsWrite, Scope: "dashboards:uid:1"},
+		})))
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+
+		require.Len(t, spy.records, 1)
+		assert.Equal(t, "1", spy.records[0].DashboardUID)
+		assert.Len(t, spy.records[0].Added, 1)
+	})
+
+	t.Run("should grant a built-in role permission scoped to a folder path prefix", func(t *testing.T) {
+		server := SetupAPITestServer(t, func(hs *HTTPServer) {
+			svc := dashboards.NewFakeDashboardService(t)
+			svc.On("GetDashboard", mock.Anything, mock.Anything).Return(&dashboards.Dashboard{ID: 1, UID: "1"}, nil)
+
+			hs.DashboardService = svc
+			hs.dashboardPermissionsService = &actest.FakePermissionsService{}
+		})
+
+		body := `{"items": [], "builtInRoles": [{ "role": "Editor", "pathPrefix": "finance/*", "permission": "View" }]}`
+		res, err := server.SendJSON(webtest.RequestWithSignedInUser(server.NewPostRequest("/api/dashboards/uid/1/permissions", strings.NewReader(body)), userWithPermissions(1, []accesscontrol.Permission{
+			{Action: dashboards.ActionDashboardsPermissionsWrite, Scope: "dashboards:uid:1"},
+			{Action: dashboards.ActionDashboardsPermissionsWrite, Scope: "folders:path:finance/*"},
+		})))
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	})
+
+	t.Run("should reject a built-in role selector scoped to a folder path the user cannot write to", func(t *testing.T) {
+		server := SetupAPITestServer(t, func(hs *HTTPServer) {
+			svc := dashboards.NewFakeDashboardService(t)
+
+			hs.DashboardService = svc
+			hs.dashboardPermissionsService = &actest.FakePermissionsService{}
+		})
+
+		// The user only has write-permission on dashboard 1 itself, not on
+		// the "finance/*" folder tree the selector tries to scope a grant to.
+		body := `{"items": [], "builtInRoles": [{ "role": "Editor", "pathPrefix": "finance/*", "permission": "View" }]}`
+		res, err := server.SendJSON(webtest.RequestWithSignedInUser(server.NewPostRequest("/api/dashboards/uid/1/permissions", strings.NewReader(body)), userWithPermissions(1, []accesscontrol.Permission{
+			{Action: dashboards.ActionDashboardsPermissionsWrite, Scope: "dashboards:uid:1"},
+		})))
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	})
+
+	t.Run("should roll back ACL items and not record an audit entry when granting a built-in role fails", func(t *testing.T) {
+		spy := &spyAuditSink{}
+		fake := &failingBuiltInRolePermissionsService{
+			FakePermissionsService: &actest.FakePermissionsService{},
+		}
+		server := SetupAPITestServer(t, func(hs *HTTPServer) {
+			svc := dashboards.NewFakeDashboardService(t)
+			svc.On("GetDashboard", mock.Anything, mock.Anything).Return(&dashboards.Dashboard{ID: 1, UID: "1"}, nil)
+
+			hs.DashboardService = svc
+			hs.dashboardPermissionsService = fake
+			hs.auditSink = spy
+		})
+
+		body := `{"items": [{ "userId": 1, "permission": "View" }], "builtInRoles": [{ "role": "Editor", "permission": "View" }]}`
+		res, err := server.SendJSON(webtest.RequestWithSignedInUser(server.NewPostRequest("/api/dashboards/uid/1/permissions", strings.NewReader(body)), userWithPermissions(1, []accesscontrol.Permission{
+			{Action: dashboards.ActionDashboardsPermissionsWrite, Scope: "dashboards:uid:1"},
+		})))
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, res.StatusCode)
+
+		var body2 struct {
+			Message string `json:"message"`
+		}
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&body2))
+		assert.Contains(t, body2.Message, "ACL items were rolled back")
+		require.NoError(t, res.Body.Close())
+
+		// The initial apply and the rollback re-apply both go through
+		// SetPermissions, restoring the dashboard's prior (empty) ACL.
+		assert.Equal(t, 2, fake.setPermissionsCalls)
+		assert.Empty(t, spy.records, "a partially-failed update must not produce an audit entry")
+	})
+
 	t.Run("should not be able to specify team and user in same acl", func(t *testing.T) {
+		spy := &spyAuditSink{}
 		server := SetupAPITestServer(t, func(hs *HTTPServer) {
 			hs.DashboardService = dashboards.NewFakeDashboardService(t)
 			hs.dashboardPermissionsService = &actest.FakePermissionsService{}
+			hs.auditSink = spy
 		})
 
 		body := `{"items": [{ userId:1, teamId: 2 }]}`
@@ -123,6 +255,7 @@ func TestHTTPServer_UpdateDashboardPermissions(t *testing.T) {
 		require.NoError(t, err)
 		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
 		require.NoError(t, res.Body.Close())
+		assert.Empty(t, spy.records, "a validation failure must not produce an audit entry")
 	})
 
 	t.Run("should not be able to specify team and role in same acl", func(t *testing.T) {
@@ -157,3 +290,263 @@ func TestHTTPServer_UpdateDashboardPermissions(t *testing.T) {
 		require.NoError(t, res.Body.Close())
 	})
 }
+
+func TestHTTPServer_BatchUpdateDashboardPermissions(t *testing.T) {
+	twoDashboards := func(svc *dashboards.FakeDashboardService) {
+		svc.On("GetDashboard", mock.Anything, mock.MatchedBy(func(q *dashboards.GetDashboardQuery) bool {
+			return q.UID == "1"
+		})).Return(&dashboards.Dashboard{ID: 1, UID: "1"}, nil)
+		svc.On("GetDashboard", mock.Anything, mock.MatchedBy(func(q *dashboards.GetDashboardQuery) bool {
+			return q.UID == "2"
+		})).Return(&dashboards.Dashboard{ID: 2, UID: "2"}, nil)
+	}
+
+	t.Run("should update permissions across dashboards in the batch", func(t *testing.T) {
+		server := SetupAPITestServer(t, func(hs *HTTPServer) {
+			svc := dashboards.NewFakeDashboardService(t)
+			twoDashboards(svc)
+			hs.DashboardService = svc
+			hs.dashboardPermissionsService = &actest.FakePermissionsService{}
+		})
+
+		body := `{"items": [{ "dashboardUid": "1", "items": [] }, { "dashboardUid": "2", "items": [] }]}`
+		res, err := server.SendJSON(webtest.RequestWithSignedInUser(server.NewPostRequest("/api/dashboards/permissions:batch", strings.NewReader(body)), userWithPermissions(1, []accesscontrol.Permission{
+			{Action: dashboards.ActionDashboardsPermissionsWrite, Scope: "dashboards:uid:1"},
+			{Action: dashboards.ActionDashboardsPermissionsWrite, Scope: "dashboards:uid:2"},
+		})))
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	})
+
+	t.Run("should reject the whole batch when missing permission on any one dashboard", func(t *testing.T) {
+		server := SetupAPITestServer(t, func(hs *HTTPServer) {
+			svc := dashboards.NewFakeDashboardService(t)
+			twoDashboards(svc)
+			hs.DashboardService = svc
+			hs.dashboardPermissionsService = &actest.FakePermissionsService{}
+		})
+
+		body := `{"items": [{ "dashboardUid": "1", "items": [] }, { "dashboardUid": "2", "items": [] }]}`
+		res, err := server.SendJSON(webtest.RequestWithSignedInUser(server.NewPostRequest("/api/dashboards/permissions:batch", strings.NewReader(body)), userWithPermissions(1, []accesscontrol.Permission{
+			{Action: dashboards.ActionDashboardsPermissionsWrite, Scope: "dashboards:uid:1"},
+		})))
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	})
+
+	t.Run("should reject the batch when an item fails validation", func(t *testing.T) {
+		server := SetupAPITestServer(t, func(hs *HTTPServer) {
+			svc := dashboards.NewFakeDashboardService(t)
+			hs.DashboardService = svc
+			hs.dashboardPermissionsService = &actest.FakePermissionsService{}
+		})
+
+		body := `{"items": [{ "dashboardUid": "1", "items": [{ "userId": 1, "teamId": 2 }] }]}`
+		res, err := server.SendJSON(webtest.RequestWithSignedInUser(server.NewPostRequest("/api/dashboards/permissions:batch", strings.NewReader(body)), userWithPermissions(1, []accesscontrol.Permission{
+			{Action: dashboards.ActionDashboardsPermissionsWrite, Scope: "dashboards:uid:1"},
+		})))
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	})
+
+	t.Run("should roll back dashboards already applied when a later one in the batch fails", func(t *testing.T) {
+		fake := &countingPermissionsService{
+			FakePermissionsService: &actest.FakePermissionsService{},
+			failOnCall:             map[string]int{"2": 1},
+		}
+		server := SetupAPITestServer(t, func(hs *HTTPServer) {
+			svc := dashboards.NewFakeDashboardService(t)
+			twoDashboards(svc)
+			hs.DashboardService = svc
+			hs.dashboardPermissionsService = fake
+		})
+
+		body := `{"items": [{ "dashboardUid": "1", "items": [] }, { "dashboardUid": "2", "items": [] }]}`
+		res, err := server.SendJSON(webtest.RequestWithSignedInUser(server.NewPostRequest("/api/dashboards/permissions:batch", strings.NewReader(body)), userWithPermissions(1, []accesscontrol.Permission{
+			{Action: dashboards.ActionDashboardsPermissionsWrite, Scope: "dashboards:uid:1"},
+			{Action: dashboards.ActionDashboardsPermissionsWrite, Scope: "dashboards:uid:2"},
+		})))
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, res.StatusCode)
+
+		var body2 struct {
+			Message string `json:"message"`
+		}
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&body2))
+		assert.Contains(t, body2.Message, "all changes were rolled back")
+		require.NoError(t, res.Body.Close())
+	})
+
+	t.Run("should tell the caller which dashboards failed to roll back", func(t *testing.T) {
+		fake := &countingPermissionsService{
+			FakePermissionsService: &actest.FakePermissionsService{},
+			failOnCall:             map[string]int{"1": 2, "2": 1},
+		}
+		server := SetupAPITestServer(t, func(hs *HTTPServer) {
+			svc := dashboards.NewFakeDashboardService(t)
+			twoDashboards(svc)
+			hs.DashboardService = svc
+			hs.dashboardPermissionsService = fake
+		})
+
+		body := `{"items": [{ "dashboardUid": "1", "items": [] }, { "dashboardUid": "2", "items": [] }]}`
+		res, err := server.SendJSON(webtest.RequestWithSignedInUser(server.NewPostRequest("/api/dashboards/permissions:batch", strings.NewReader(body)), userWithPermissions(1, []accesscontrol.Permission{
+			{Action: dashboards.ActionDashboardsPermissionsWrite, Scope: "dashboards:uid:1"},
+			{Action: dashboards.ActionDashboardsPermissionsWrite, Scope: "dashboards:uid:2"},
+		})))
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, res.StatusCode)
+
+		var body2 struct {
+			Message string `json:"message"`
+		}
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&body2))
+		assert.Contains(t, body2.Message, "rollback also failed")
+		assert.Contains(t, body2.Message, "1")
+		require.NoError(t, res.Body.Close())
+	})
+}
+
+// failingBuiltInRolePermissionsService wraps FakePermissionsService, always
+// failing SetBuiltInRolePermissions while counting SetPermissions calls, to
+// exercise UpdateDashboardPermissions' rollback of an already-applied ACL
+// item write when granting a built-in role afterwards fails.
+type failingBuiltInRolePermissionsService struct {
+	*actest.FakePermissionsService
+	setPermissionsCalls int
+}
+
+func (s *failingBuiltInRolePermissionsService) SetPermissions(ctx context.Context, orgID int64, resourceID string, commands ...accesscontrol.SetResourcePermissionCommand) ([]accesscontrol.ResourcePermission, error) {
+	s.setPermissionsCalls++
+	return s.FakePermissionsService.SetPermissions(ctx, orgID, resourceID, commands...)
+}
+
+func (s *failingBuiltInRolePermissionsService) SetBuiltInRolePermissions(ctx context.Context, orgID, resourceID int64, builtInRole, pathPrefix, permission string) ([]accesscontrol.ResourcePermission, error) {
+	return nil, errors.New("failed to grant built-in role permission")
+}
+
+// countingPermissionsService wraps FakePermissionsService and lets a test
+// make a specific numbered SetPermissions call for a given resource ID fail,
+// so BatchUpdateDashboardPermissions' apply-then-rollback behavior can be
+// exercised deterministically.
+type countingPermissionsService struct {
+	*actest.FakePermissionsService
+	failOnCall map[string]int
+	calls      map[string]int
+}
+
+func (s *countingPermissionsService) SetPermissions(ctx context.Context, orgID int64, resourceID string, commands ...accesscontrol.SetResourcePermissionCommand) ([]accesscontrol.ResourcePermission, error) {
+	if s.calls == nil {
+		s.calls = map[string]int{}
+	}
+	s.calls[resourceID]++
+	if n, ok := s.failOnCall[resourceID]; ok && s.calls[resourceID] == n {
+		return nil, errors.New("failed to set permissions")
+	}
+	return s.FakePermissionsService.SetPermissions(ctx, orgID, resourceID, commands...)
+}
+
+func TestHTTPServer_GetDashboardEffectivePermissions(t *testing.T) {
+	t.Run("should return the effective permission trace for a user", func(t *testing.T) {
+		server := SetupAPITestServer(t, func(hs *HTTPServer) {
+			svc := dashboards.NewFakeDashboardService(t)
+			svc.On("GetDashboard", mock.Anything, mock.Anything).Return(&dashboards.Dashboard{ID: 1, UID: "1"}, nil)
+			hs.DashboardService = svc
+			hs.dashboardPermissionsService = &explainFakePermissionsService{
+				FakePermissionsService: &actest.FakePermissionsService{},
+				trace:                  &DashboardEffectivePermissions{UserID: 2, UserLogin: "regular", Actions: map[string]bool{}},
+			}
+		})
+
+		res, err := server.Send(webtest.RequestWithSignedInUser(server.NewGetRequest("/api/dashboards/uid/1/permissions/effective?userId=2"), userWithPermissions(1, []accesscontrol.Permission{
+			{Action: dashboards.ActionDashboardsPermissionsRead, Scope: "dashboards:uid:1"},
+		})))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		var result DashboardEffectivePermissions
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&result))
+		assert.Equal(t, "1", result.DashboardUID)
+		assert.Equal(t, "regular", result.UserLogin)
+		require.NoError(t, res.Body.Close())
+	})
+
+	t.Run("should not hide the explained user even if they are a hidden user", func(t *testing.T) {
+		server := SetupAPITestServer(t, func(hs *HTTPServer) {
+			cfg := setting.NewCfg()
+			cfg.HiddenUsers = map[string]struct{}{"hidden": {}}
+			hs.Cfg = cfg
+
+			svc := dashboards.NewFakeDashboardService(t)
+			svc.On("GetDashboard", mock.Anything, mock.Anything).Return(&dashboards.Dashboard{ID: 1, UID: "1"}, nil)
+			hs.DashboardService = svc
+			hs.dashboardPermissionsService = &explainFakePermissionsService{
+				FakePermissionsService: &actest.FakePermissionsService{},
+				trace:                  &DashboardEffectivePermissions{UserID: 2, UserLogin: "hidden", Actions: map[string]bool{}},
+			}
+		})
+
+		res, err := server.Send(webtest.RequestWithSignedInUser(server.NewGetRequest("/api/dashboards/uid/1/permissions/effective?userId=2"), userWithPermissions(1, []accesscontrol.Permission{
+			{Action: dashboards.ActionDashboardsPermissionsRead, Scope: "dashboards:uid:1"},
+		})))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		var result DashboardEffectivePermissions
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&result))
+		assert.Equal(t, "hidden", result.UserLogin)
+		require.NoError(t, res.Body.Close())
+	})
+
+	t.Run("should require a userId query parameter", func(t *testing.T) {
+		server := SetupAPITestServer(t, func(hs *HTTPServer) {
+			svc := dashboards.NewFakeDashboardService(t)
+			hs.DashboardService = svc
+			hs.dashboardPermissionsService = &explainFakePermissionsService{FakePermissionsService: &actest.FakePermissionsService{}}
+		})
+
+		res, err := server.Send(webtest.RequestWithSignedInUser(server.NewGetRequest("/api/dashboards/uid/1/permissions/effective"), userWithPermissions(1, []accesscontrol.Permission{
+			{Action: dashboards.ActionDashboardsPermissionsRead, Scope: "dashboards:uid:1"},
+		})))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	})
+}
+
+// explainFakePermissionsService wraps FakePermissionsService and stubs
+// Explain, which the generated fake doesn't cover, so
+// GetDashboardEffectivePermissions can be tested without a real permission
+// resolution engine.
+type explainFakePermissionsService struct {
+	*actest.FakePermissionsService
+	trace *DashboardEffectivePermissions
+	err   error
+}
+
+func (s *explainFakePermissionsService) Explain(_ context.Context, _ int64, _ int64) (*DashboardEffectivePermissions, error) {
+	return s.trace, s.err
+}
+
+// spyAuditSink is an in-memory AuditSink used to assert which dashboard ACL
+// mutations produced an audit record in tests.
+type spyAuditSink struct {
+	records []DashboardPermissionAuditRecord
+}
+
+func (s *spyAuditSink) RecordDashboardPermissionChange(_ context.Context, record DashboardPermissionAuditRecord) error {
+	s.records = append(s.records, record)
+	return nil
+}
+
+func (s *spyAuditSink) ListDashboardPermissionAudit(_ context.Context, orgID int64, dashboardUID string, limit, offset int) ([]DashboardPermissionAuditRecord, int64, error) {
+	return s.records, int64(len(s.records)), nil
+}